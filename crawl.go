@@ -1,19 +1,19 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
-	"path"
-	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 const (
@@ -24,14 +24,56 @@ const (
 type Lookup struct {
 	URL   string
 	Depth int
+	// ackToken identifies the delivery a Frontier.Pop handed out, so the
+	// matching Ack call can acknowledge the right one. Unexported so queue
+	// implementations that don't need it (InMemoryFrontier) and JSON
+	// encoding (AMQPFrontier.Push) never see it.
+	ackToken uint64
 }
 
 // Opts provides options for the crawler
 type Opts struct {
-	Parallel int
-	MaxDepth int
-	Verbose  bool
-	Limit    time.Duration
+	Parallel    int
+	MaxDepth    int
+	Verbose     bool
+	Limit       time.Duration
+	Extractors  []Extractor
+	OfflineDir  string
+	CrossDomain bool
+	Throttle    time.Duration
+	Robots      bool
+	Sitemap     bool
+	Archives    bool
+	// AMQPURL, when set, backs the Frontier with RabbitMQ instead of an
+	// in-memory channel, giving the queue durability and crash-safe
+	// redelivery within this process. Pending completion tracking is still
+	// process-local, so running more than one process against the same
+	// queue is not yet safe: a message popped by a different process than
+	// the one that pushed it will never be accounted for correctly.
+	AMQPURL string
+	// RedisAddr, when set, backs Visited with Redis instead of a local map,
+	// so multiple worker processes can share a single seen-set
+	RedisAddr string
+	// UserAgents supplies the User-Agent sent with every request. Left nil,
+	// NewCrawler fills in a default pool, but tests can inject a
+	// deterministic one.
+	UserAgents      *UserAgentPool
+	StickyUserAgent bool
+	// Insecure skips TLS certificate verification
+	Insecure bool
+	// Proxy is a single proxy URL to route requests through
+	Proxy string
+	// ProxyFile points at a newline separated list of proxy URLs to rotate
+	// through per request, one per line
+	ProxyFile string
+	// Output is the path results are written to. Left empty, results go to
+	// stdout.
+	Output string
+	// Format selects the ResultSink: "text" (default), "json", or "csv"
+	Format string
+	// LogPath, when set, appends an ErrorRecord for every failed Lookup to
+	// the file at this path
+	LogPath string
 }
 
 // ExceedsMaxDepth checks if given depth exceeds max depth specified in the options struct
@@ -46,16 +88,29 @@ type HTTPClient interface {
 
 // Crawler crawler is the client responsible for crawling URLs
 type Crawler struct {
-	Start      time.Time
-	Ctx        context.Context
-	HTTPClient HTTPClient
-	StartURL   string
-	Host       string
-	Counter    map[string]*Count
-	Opts       *Opts
-	URLChan    chan Lookup
-	ResChan    chan int
-	Mu         *sync.Mutex
+	Start         time.Time
+	Ctx           context.Context
+	HTTPClient    HTTPClient
+	StartURL      string
+	Host          string
+	Counter       map[string]*Count
+	Opts          *Opts
+	Mu            *sync.Mutex
+	OfflineWriter *OfflineWriter
+	Robots        *RobotsRules
+	// RobotsSitemaps holds the Sitemap: directives found in robots.txt, for
+	// a SitemapProvider to expand when -sitemap is enabled
+	RobotsSitemaps []string
+	Frontier       Frontier
+	Visited        Visited
+	// Pending tracks URLs pushed onto the Frontier that haven't been
+	// acknowledged yet, so waitUntilDone knows when a single-process crawl
+	// has actually finished
+	Pending    *sync.WaitGroup
+	Logger     Logger
+	ResultSink ResultSink
+	// ErrorLog records failed Lookups to disk when Opts.LogPath is set
+	ErrorLog *FileErrorLogger
 }
 
 // Count object representing word and number count of a URL
@@ -66,68 +121,112 @@ type Count struct {
 }
 
 // Lookup looks up given URL
-func (c *Crawler) Lookup(url string, depth int) ([]string, error) {
-	_, ok := c.readMap(url)
-	if ok {
+func (c *Crawler) Lookup(rawURL string, depth int) (nextURLs []string, err error) {
+	var statusCode int
+	defer func() {
+		if err != nil {
+			c.logLookupError(rawURL, depth, statusCode, err)
+		}
+	}()
+
+	seen, err := c.Visited.MarkSeen(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if seen {
 		// url already visited
 		return nil, nil
 	}
-	c.writeMap(url, &Count{})
 
 	if c.Opts.ExceedsMaxDepth(depth) {
 		// Halt in case max depth has been exceeded
 		return nil, nil
 	}
-	if c.Opts.Verbose {
-		fmt.Printf("Visiting: %s on depth: %d\n", url, depth)
+	pageURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if !c.allowed(pageURL) {
+		// Disallowed by robots.txt
+		return nil, nil
 	}
-	req, err := http.NewRequestWithContext(c.Ctx, "GET", url, nil)
+	c.Logger.Debug("visiting url", "url", rawURL, "depth", depth)
+	req, err := http.NewRequestWithContext(c.Ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("User-Agent", c.Opts.UserAgents.Pick(pageURL.Host))
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	statusCode = resp.StatusCode
 	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			panic(fmt.Sprintf("unable to close response body: %s", err.Error()))
+		if cerr := resp.Body.Close(); cerr != nil {
+			panic(fmt.Sprintf("unable to close response body: %s", cerr.Error()))
 		}
 	}()
-	b, err := ioutil.ReadAll(resp.Body)
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(b))
 	if err != nil {
 		return nil, err
 	}
-	htmlBody, nextURLs := c.preProcessHTMLString(string(b))
-	c.countWordsAndNumbers(url, htmlBody)
+	c.runExtractors(rawURL, doc, pageURL)
+
+	if c.OfflineWriter != nil {
+		if saveErr := c.OfflineWriter.Save(rawURL, b); saveErr != nil {
+			c.Logger.Debug("error saving offline copy", "url", rawURL, "error", saveErr.Error())
+		}
+	}
+
+	nextURLs = c.GetNextURLs(doc, pageURL)
 	if c.Opts.ExceedsMaxDepth(depth + 1) {
 		return []string{}, nil
 	}
 	return nextURLs, nil
 }
 
-func (c *Crawler) countWordsAndNumbers(url, html string) {
-	if html == "" {
-		c.writeMap(url, &Count{})
+// logLookupError reports a failed Lookup through the Logger and, when
+// Opts.LogPath is set, appends an ErrorRecord to the error log
+func (c *Crawler) logLookupError(rawURL string, depth, statusCode int, err error) {
+	c.Logger.Error("error looking up url", "url", rawURL, "depth", depth, "error", err.Error())
+	if c.ErrorLog == nil {
 		return
 	}
-	words := strings.Split(html, " ")
-	numbers := 0
+	rec := ErrorRecord{URL: rawURL, Depth: depth, StatusCode: statusCode, Error: err.Error()}
+	if logErr := c.ErrorLog.Log(rec); logErr != nil {
+		c.Logger.Error("error writing to error log", "error", logErr.Error())
+	}
+}
 
-	for _, word := range words {
-		// Check if words can be parsed as float
-		_, err := strconv.ParseFloat(word, 64)
-		if err == nil {
-			numbers++
-		}
+// runExtractors runs every configured Extractor against doc and aggregates
+// the resulting word/number counts into the Crawler's Counter. Callers that
+// register no Extractors get the original word/number counting behaviour.
+func (c *Crawler) runExtractors(pageURL string, doc *goquery.Document, u *url.URL) {
+	extractors := c.Opts.Extractors
+	if len(extractors) == 0 {
+		extractors = []Extractor{WordCountExtractor{}}
 	}
-	count := Count{
-		Numbers: numbers,
-		Words:   len(words) - numbers,
+	count := Count{}
+	for _, extractor := range extractors {
+		res, err := extractor.Extract(doc, u)
+		if err != nil {
+			c.Logger.Debug("extractor error", "url", pageURL, "error", err.Error())
+			continue
+		}
+		count.Words += res.Words
+		count.Numbers += res.Numbers
 	}
+	c.writeMap(pageURL, &count)
+}
 
-	c.writeMap(url, &count)
+// allowed reports whether u may be crawled under the rules parsed from
+// robots.txt, always true if no robots rules were loaded
+func (c *Crawler) allowed(u *url.URL) bool {
+	return c.Robots.Allowed(u.Path)
 }
 
 func (c *Crawler) readMap(key string) (*Count, bool) {
@@ -143,37 +242,6 @@ func (c *Crawler) writeMap(key string, val *Count) {
 	c.Counter[key] = val
 }
 
-func (c *Crawler) preProcessHTMLString(html string) (string, []string) {
-	html = c.getHTMLBodyString(html)
-	html = strings.ReplaceAll(html, "\n", "")
-	nextURLs := c.GetNextURLs(html)
-
-	re := regexp.MustCompile(`<[^>]*>`)
-	res := re.ReplaceAllString(html, "")
-	return strings.TrimSpace(res), nextURLs
-}
-
-func (c *Crawler) getHTMLBodyString(html string) string {
-	re := regexp.MustCompile(`<body\b[^>]*>([\s\S]*?)<\/body>`)
-	body := re.FindAllString(html, -1)
-	if len(body) == 0 {
-		if strings.HasPrefix(html, "<?xml") {
-			return html
-		}
-		return ""
-	}
-
-	// Remove possible script elements nested in the body tag
-	re = regexp.MustCompile(`<script\b[^>]*>([\s\S]*?)<\/script>`)
-	res := re.ReplaceAllString(body[0], "")
-
-	// Remove possible style elements nested in the body tag
-	re = regexp.MustCompile(`<style\b[^>]*>([\s\S]*?)<\/style>`)
-	res = re.ReplaceAllString(res, "")
-
-	return res
-}
-
 func initialise() (string, *Opts) {
 	args := os.Args[1:]
 	if len(args) == 0 {
@@ -187,6 +255,21 @@ func initialise() (string, *Opts) {
 		fmt.Println("use -p to indicate the amount of parallel threads")
 		fmt.Println("use -v to run the crawler in verbose mode")
 		fmt.Println("use -limit to specify the time interval to wait between requests")
+		fmt.Println("use -o to mirror the crawled pages to the given directory")
+		fmt.Println("use -cross-domain to also follow links to other hosts, needed to mirror external assets")
+		fmt.Println("use -throttle to specify the time interval to wait between offline mirror writes")
+		fmt.Println("use -robots to seed from and honor the host's robots.txt")
+		fmt.Println("use -sitemap to seed from the host's sitemap.xml")
+		fmt.Println("use -archives to seed from web.archive.org and Common Crawl")
+		fmt.Println("use -amqp-url to back the frontier with RabbitMQ for durable, crash-safe redelivery (single process only; see Opts.AMQPURL)")
+		fmt.Println("use -redis-addr to back the visited set with Redis so workers can share it")
+		fmt.Println("use -sticky-ua to reuse the same User-Agent for every request to a given host")
+		fmt.Println("use -insecure to skip TLS certificate verification")
+		fmt.Println("use -proxy to route requests through a single proxy URL")
+		fmt.Println("use -proxy-file to rotate requests through a newline separated list of proxy URLs")
+		fmt.Println("use -output to write results to the given path instead of stdout")
+		fmt.Println("use -format to select the result format: text, json, or csv")
+		fmt.Println("use -log to capture failed requests as JSON lines at the given path")
 		return "", nil
 	}
 	if !strings.HasPrefix(inputURL, "http") {
@@ -201,14 +284,69 @@ func initialise() (string, *Opts) {
 	pFlag := flag.Int("p", 1, "Use p to provide the amount of parallel requests which can be executed")
 	vFlag := flag.Bool("v", false, "Use v to indicate running the crawler in verbose mode")
 	limitFlag := flag.Int("limit", 0, "Use d to provide the max depth for the crawler to search")
+	oFlag := flag.String("o", "", "Use o to mirror the crawled pages to the given directory")
+	crossDomainFlag := flag.Bool("cross-domain", false, "Use cross-domain to also follow links to other hosts, needed to mirror external assets")
+	throttleFlag := flag.Int("throttle", 0, "Use throttle to specify the time interval to wait between offline mirror writes")
+	robotsFlag := flag.Bool("robots", false, "Use robots to seed from and honor the host's robots.txt")
+	sitemapFlag := flag.Bool("sitemap", false, "Use sitemap to seed from the host's sitemap.xml")
+	archivesFlag := flag.Bool("archives", false, "Use archives to seed from web.archive.org and Common Crawl")
+	amqpURLFlag := flag.String("amqp-url", "", "Use amqp-url to back the frontier with RabbitMQ for durable, crash-safe redelivery (single process only)")
+	redisAddrFlag := flag.String("redis-addr", "", "Use redis-addr to back the visited set with Redis so workers can share it")
+	stickyUAFlag := flag.Bool("sticky-ua", false, "Use sticky-ua to reuse the same User-Agent for every request to a given host")
+	insecureFlag := flag.Bool("insecure", false, "Use insecure to skip TLS certificate verification")
+	proxyFlag := flag.String("proxy", "", "Use proxy to route requests through a single proxy URL")
+	proxyFileFlag := flag.String("proxy-file", "", "Use proxy-file to rotate requests through a newline separated list of proxy URLs")
+	outputFlag := flag.String("output", "", "Use output to write results to the given path instead of stdout")
+	formatFlag := flag.String("format", "", "Use format to select the result format: text, json, or csv (defaults to text)")
+	logFlag := flag.String("log", "", "Use log to capture failed requests as JSON lines at the given path")
 
 	flag.Parse()
 	return inputURL, &Opts{
-		MaxDepth: *dFlag,
-		Parallel: *pFlag,
-		Verbose:  *vFlag,
-		Limit:    time.Millisecond * time.Duration(*limitFlag),
+		MaxDepth:        *dFlag,
+		Parallel:        *pFlag,
+		Verbose:         *vFlag,
+		Limit:           time.Millisecond * time.Duration(*limitFlag),
+		OfflineDir:      *oFlag,
+		CrossDomain:     *crossDomainFlag,
+		Throttle:        time.Millisecond * time.Duration(*throttleFlag),
+		Robots:          *robotsFlag,
+		Sitemap:         *sitemapFlag,
+		Archives:        *archivesFlag,
+		AMQPURL:         *amqpURLFlag,
+		RedisAddr:       *redisAddrFlag,
+		StickyUserAgent: *stickyUAFlag,
+		Insecure:        *insecureFlag,
+		Proxy:           *proxyFlag,
+		ProxyFile:       *proxyFileFlag,
+		Output:          *outputFlag,
+		Format:          *formatFlag,
+		LogPath:         *logFlag,
+	}
+}
+
+const (
+	amqpURLsQueue    = "crawl.urls"
+	amqpResultsQueue = "crawl.results"
+	redisVisitedTTL  = 24 * time.Hour
+)
+
+// buildDependencies constructs the Frontier and Visited backing a Crawler,
+// falling back to in-memory implementations when opts doesn't request a
+// shared backing
+func buildDependencies(opts *Opts) (Frontier, Visited, error) {
+	var frontier Frontier = NewInMemoryFrontier()
+	if opts.AMQPURL != "" {
+		amqpFrontier, err := NewAMQPFrontier(opts.AMQPURL, amqpURLsQueue, amqpResultsQueue)
+		if err != nil {
+			return nil, nil, err
+		}
+		frontier = amqpFrontier
 	}
+	var visited Visited = NewInMemoryVisited()
+	if opts.RedisAddr != "" {
+		visited = NewRedisVisited(opts.RedisAddr, redisVisitedTTL)
+	}
+	return frontier, visited, nil
 }
 
 func main() {
@@ -217,11 +355,22 @@ func main() {
 		return
 	}
 
+	frontier, visited, err := buildDependencies(opts)
+	if err != nil {
+		panic(err)
+	}
+	transport, err := buildTransport(opts)
+	if err != nil {
+		panic(err)
+	}
 	crawler, err := NewCrawler(url,
 		&http.Client{
-			Timeout: requestTimeout,
+			Timeout:   requestTimeout,
+			Transport: transport,
 		},
 		opts,
+		frontier,
+		visited,
 	)
 	if err != nil {
 		panic(err)
@@ -233,130 +382,151 @@ func main() {
 		}()
 	}
 
-	// Put the initial URL in the url channel
-	crawler.URLChan <- Lookup{
+	crawler.seed()
+
+	// Put the initial URL on the frontier
+	crawler.push(Lookup{
 		URL:   url,
 		Depth: 0,
-	}
+	})
 
 	crawler.waitUntilDone()
 }
 
-// waitUntilDone wait until all requests are executed and print the result
+// seed runs the SeedProviders enabled through Opts before the initial URL is
+// pushed, so robots.txt rules and discovered sitemap/archive URLs are in
+// place before the crawl proper starts. It returns how many extra Lookups
+// were queued, for callers that want to log it.
+func (c *Crawler) seed() int {
+	var providers []SeedProvider
+	if c.Opts.Robots {
+		providers = append(providers, RobotsProvider{HTTPClient: c.HTTPClient})
+	}
+	if c.Opts.Sitemap {
+		providers = append(providers, SitemapProvider{HTTPClient: c.HTTPClient})
+	}
+	if c.Opts.Archives {
+		providers = append(providers, ArchiveProvider{HTTPClient: c.HTTPClient})
+	}
+
+	queued := 0
+	for _, provider := range providers {
+		n, err := provider.Seed(c)
+		if err != nil {
+			c.Logger.Debug("seed provider error", "error", err.Error())
+		}
+		queued += n
+	}
+	if c.Opts.Sitemap && len(c.RobotsSitemaps) > 0 {
+		n, err := (SitemapProvider{HTTPClient: c.HTTPClient, Locations: c.RobotsSitemaps}).Seed(c)
+		if err != nil {
+			c.Logger.Debug("seed provider error", "error", err.Error())
+		}
+		queued += n
+	}
+	return queued
+}
+
+// waitUntilDone blocks until every Lookup pushed onto the Frontier has been
+// acknowledged, then writes the result
 func (c *Crawler) waitUntilDone() {
-	countTotalRequests := 1
-	requestsDone := 0
-	for {
-		res := <-c.ResChan
-		countTotalRequests += res
-		requestsDone++
-		if requestsDone == countTotalRequests {
-			break
+	c.Pending.Wait()
+	c.Logger.Debug("visited urls", "count", len(c.Counter))
+	c.writeResults(c.Ctx)
+	if c.OfflineWriter != nil {
+		if err := c.OfflineWriter.RewriteLinks(); err != nil {
+			c.Logger.Error("error rewriting offline mirror links", "error", err.Error())
 		}
 	}
-	if c.Opts.Verbose {
-		fmt.Printf("Visited: %d URLS\n", len(c.Counter))
+}
+
+// push schedules l on the Frontier, tracking it as pending so waitUntilDone
+// can tell when a single-process crawl has actually finished
+func (c *Crawler) push(l Lookup) {
+	c.Pending.Add(1)
+	if err := c.Frontier.Push(l); err != nil {
+		c.Logger.Debug("error pushing url onto frontier", "url", l.URL, "error", err.Error())
+		c.Pending.Done()
 	}
-	c.PrintResults()
 }
 
-// processURLs process urls from the url channel
+// processURLs consumes Lookups from the Frontier until it is cancelled,
+// acknowledging each one once its newly discovered URLs have been pushed back
 func (c *Crawler) processURLs() {
 	for {
-		lookup := <-c.URLChan
+		lookup, err := c.Frontier.Pop(c.Ctx)
+		if err != nil {
+			return
+		}
 		// Wait limit specified between requests
 		time.Sleep(c.Opts.Limit)
-		// urlChannel
 		nextURLs, err := c.Lookup(lookup.URL, lookup.Depth)
 		if err != nil {
-			fmt.Printf("error looking up url: %s\n", err.Error())
-			c.ResChan <- 0
+			if ackErr := c.Frontier.Ack(lookup, 0); ackErr != nil {
+				c.Logger.Debug("error acking url", "url", lookup.URL, "error", ackErr.Error())
+			}
+			c.Pending.Done()
 			continue
 		}
-		// Add new URLs to the channel in a goroutine
-		go func(next []string, curDepth int) {
-			for _, url := range next {
-				c.URLChan <- Lookup{
-					URL:   url,
-					Depth: curDepth + 1,
-				}
-			}
-		}(nextURLs, lookup.Depth)
-		c.ResChan <- len(nextURLs)
+		queueSeeds(c, nextURLs, lookup.Depth+1)
+		if err := c.Frontier.Ack(lookup, len(nextURLs)); err != nil {
+			c.Logger.Debug("error acking url", "url", lookup.URL, "error", err.Error())
+		}
+		c.Pending.Done()
 	}
 }
 
-// GetNextURLs retrieve the next URLs pointing to other URLS
-// on the same host
-func (c *Crawler) GetNextURLs(htmlBody string) []string {
+// GetNextURLs retrieves the next URLs pointing to other URLs on the same
+// host (or on any host when Opts.CrossDomain is set), resolving
+// anchors/links/scripts/images found in doc against pageURL
+func (c *Crawler) GetNextURLs(doc *goquery.Document, pageURL *url.URL) []string {
+	links, err := LinkExtractor{}.Extract(doc, pageURL)
+	if err != nil {
+		return nil
+	}
 	res := []string{}
-	// Use map to create distinct URLs
-	resMap := make(map[string]struct{})
-	re := regexp.MustCompile(`href="(.*?)"`)
-	// Find al hrefs
-	urls := re.FindAllString(htmlBody, -1)
-	for _, href := range urls {
-		trimmedURL := href[6 : len(href)-1]
-		if strings.HasPrefix(trimmedURL, "http") {
-			url, err := url.Parse(trimmedURL)
-			// If valid URL and same host append
-			if err != nil || url.Host != c.Host {
-				continue
-			}
-		} else {
-			if strings.HasPrefix(trimmedURL, "/") {
-				// If href just a path append it to the current host
-				trimmedURL = path.Join(c.Host, href[6:len(href)-1])
-			}
-			_, err := url.Parse(trimmedURL)
-			if err == nil {
-				trimmedURL = fmt.Sprintf("https://%s", trimmedURL)
-			}
-		}
-		url, err := url.Parse(trimmedURL)
+	for _, raw := range links.URLs {
+		u, err := url.Parse(raw)
 		if err != nil {
 			continue
 		}
-		if url.Host != c.Host {
+		if u.Host != c.Host && !c.Opts.CrossDomain {
 			continue
 		}
-		resMap[fmt.Sprintf("https://%s", path.Join(url.Host, url.Path))] = struct{}{}
-	}
-	// Create slice of resulting URLs
-	for k := range resMap {
-		res = append(res, k)
+		res = append(res, u.String())
 	}
-
 	return res
 }
 
-// PrintResults prints the results of the gathered pages
-func (c *Crawler) PrintResults() {
+// writeResults sends every gathered Count through the configured ResultSink
+func (c *Crawler) writeResults(ctx context.Context) {
 	totalWords := 0
 	totalNumbers := 0
 	for path, count := range c.Counter {
-		url, err := url.Parse(path)
-		if err != nil {
+		out := *count
+		out.URL = path
+		if err := c.ResultSink.Write(ctx, out); err != nil {
+			c.Logger.Error("error writing result", "url", path, "error", err.Error())
 			continue
 		}
-		fmt.Printf("%s\t\t%d\t%d\t\t%s\n", url.Host, count.Words, count.Numbers, url.Path)
 		totalWords += count.Words
 		totalNumbers += count.Numbers
 	}
-	if c.Opts.Verbose {
-		fmt.Printf("Found %d words and %d numbers for base URL %s with depth %d\n", totalWords, totalNumbers, c.StartURL, c.Opts.MaxDepth)
-		fmt.Printf("Execution duration: %s\n", time.Since(c.Start))
+	c.Logger.Debug("found words and numbers", "words", totalWords, "numbers", totalNumbers, "start_url", c.StartURL, "max_depth", c.Opts.MaxDepth)
+	c.Logger.Debug("execution duration", "duration", time.Since(c.Start).String())
+	if err := c.ResultSink.Close(); err != nil {
+		c.Logger.Error("error closing result sink", "error", err.Error())
 	}
 }
 
 // NewCrawler creates a new crawler
-func NewCrawler(startURL string, httpClient HTTPClient, opts *Opts) (*Crawler, error) {
+func NewCrawler(startURL string, httpClient HTTPClient, opts *Opts, frontier Frontier, visited Visited) (*Crawler, error) {
 	u, err := url.Parse(startURL)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Crawler{
+	c := &Crawler{
 		Start:      time.Now(),
 		Ctx:        context.Background(),
 		HTTPClient: httpClient,
@@ -364,8 +534,29 @@ func NewCrawler(startURL string, httpClient HTTPClient, opts *Opts) (*Crawler, e
 		Host:       u.Host,
 		Counter:    make(map[string]*Count),
 		Opts:       opts,
-		URLChan:    make(chan Lookup),
-		ResChan:    make(chan int),
 		Mu:         &sync.Mutex{},
-	}, nil
+		Frontier:   frontier,
+		Visited:    visited,
+		Pending:    &sync.WaitGroup{},
+	}
+	if opts.OfflineDir != "" {
+		c.OfflineWriter = NewOfflineWriter(opts.OfflineDir, opts.Throttle)
+	}
+	if opts.UserAgents == nil {
+		opts.UserAgents = NewUserAgentPool(nil, opts.StickyUserAgent)
+	}
+	c.Logger = NewStdLogger(opts.Verbose)
+	sink, err := buildResultSink(opts)
+	if err != nil {
+		return nil, err
+	}
+	c.ResultSink = sink
+	if opts.LogPath != "" {
+		errorLog, err := NewFileErrorLogger(opts.LogPath)
+		if err != nil {
+			return nil, err
+		}
+		c.ErrorLog = errorLog
+	}
+	return c, nil
 }
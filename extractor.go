@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ExtractResult holds everything a single Extractor derived from one page
+type ExtractResult struct {
+	Words   int
+	Numbers int
+	URLs    []string
+	Matches []string
+}
+
+// Extractor is implemented by anything that derives data from a parsed page.
+// The Crawler runs every configured Extractor against each page it visits.
+type Extractor interface {
+	Extract(doc *goquery.Document, pageURL *url.URL) (ExtractResult, error)
+}
+
+// WordCountExtractor counts words and numbers found in the page body text
+type WordCountExtractor struct{}
+
+// Extract implements Extractor
+func (WordCountExtractor) Extract(doc *goquery.Document, _ *url.URL) (ExtractResult, error) {
+	text := strings.TrimSpace(doc.Find("body").Text())
+	if text == "" {
+		return ExtractResult{}, nil
+	}
+	fields := strings.Fields(text)
+	numbers := 0
+	for _, field := range fields {
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			numbers++
+		}
+	}
+	return ExtractResult{
+		Words:   len(fields) - numbers,
+		Numbers: numbers,
+	}, nil
+}
+
+// LinkExtractor collects URLs referenced by anchors, stylesheets, scripts and
+// images, resolving relative references against the page they were found on
+type LinkExtractor struct{}
+
+// linkAttrs maps the tags LinkExtractor inspects to the attribute holding the URL
+var linkAttrs = map[string]string{
+	"a":      "href",
+	"link":   "href",
+	"script": "src",
+	"img":    "src",
+}
+
+// Extract implements Extractor
+func (LinkExtractor) Extract(doc *goquery.Document, pageURL *url.URL) (ExtractResult, error) {
+	seen := make(map[string]struct{})
+	for tag, attr := range linkAttrs {
+		doc.Find(tag).Each(func(_ int, sel *goquery.Selection) {
+			raw, ok := sel.Attr(attr)
+			if !ok || raw == "" {
+				return
+			}
+			ref, err := url.Parse(raw)
+			if err != nil {
+				return
+			}
+			seen[pageURL.ResolveReference(ref).String()] = struct{}{}
+		})
+	}
+	urls := make([]string, 0, len(seen))
+	for u := range seen {
+		urls = append(urls, u)
+	}
+	return ExtractResult{URLs: urls}, nil
+}
+
+// RegexExtractor matches a user-supplied pattern against the page body text,
+// capping the number of matches returned at MaxMatches
+type RegexExtractor struct {
+	Pattern    *regexp.Regexp
+	MaxMatches int
+}
+
+// Extract implements Extractor
+func (r RegexExtractor) Extract(doc *goquery.Document, _ *url.URL) (ExtractResult, error) {
+	if r.Pattern == nil {
+		return ExtractResult{}, fmt.Errorf("regex extractor: no pattern configured")
+	}
+	max := r.MaxMatches
+	if max <= 0 {
+		max = -1
+	}
+	matches := r.Pattern.FindAllString(doc.Find("body").Text(), max)
+	return ExtractResult{Matches: matches}, nil
+}
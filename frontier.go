@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/streadway/amqp"
+)
+
+// Frontier is the queue of URLs still to be crawled. It is the extension
+// point that lets the crawler scale from a single in-memory channel to
+// workers spread across many machines.
+type Frontier interface {
+	// Push schedules l to be crawled
+	Push(l Lookup) error
+	// Pop blocks until a URL is available or ctx is cancelled
+	Pop(ctx context.Context) (Lookup, error)
+	// Ack marks l as fully processed, having discovered nextURLCount further
+	// URLs, so a queue-backed implementation can acknowledge its delivery
+	Ack(l Lookup, nextURLCount int) error
+}
+
+// InMemoryFrontier is a Frontier backed by an unbuffered channel, preserving
+// the crawler's original single-process semantics
+type InMemoryFrontier struct {
+	urlChan chan Lookup
+}
+
+// NewInMemoryFrontier creates a Frontier that only ever holds work in this
+// process's memory
+func NewInMemoryFrontier() *InMemoryFrontier {
+	return &InMemoryFrontier{urlChan: make(chan Lookup)}
+}
+
+// Push implements Frontier
+func (f *InMemoryFrontier) Push(l Lookup) error {
+	f.urlChan <- l
+	return nil
+}
+
+// Pop implements Frontier
+func (f *InMemoryFrontier) Pop(ctx context.Context) (Lookup, error) {
+	select {
+	case l := <-f.urlChan:
+		return l, nil
+	case <-ctx.Done():
+		return Lookup{}, ctx.Err()
+	}
+}
+
+// Ack implements Frontier. The in-memory frontier has no redelivery
+// semantics to acknowledge, so this is a no-op.
+func (f *InMemoryFrontier) Ack(Lookup, int) error {
+	return nil
+}
+
+// amqpPublisher is the subset of *amqp.Channel that Push/Ack need, letting
+// tests substitute a fake instead of dialing a real broker
+type amqpPublisher interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// AMQPFrontier is a Frontier backed by RabbitMQ, modeled on the Trandoshan
+// crawler architecture: a durable queue holds URLs still to crawl, a second
+// durable queue receives extracted results, and messages are only acked
+// once Lookup has actually finished so a crashed worker's in-flight
+// messages are redelivered instead of lost.
+//
+// The urls/results queues themselves support competing consumers across
+// processes, but Crawler.Pending (a process-local sync.WaitGroup) does not:
+// a message pushed by one process can be delivered to and acked by another,
+// which that other process's Pending was never incremented for. Until
+// completion tracking is made cross-process aware (e.g. reconciling
+// pushed/acked counts via resultsQueue, or an external counter alongside
+// Visited), only run a single process against a given urlsQueue.
+type AMQPFrontier struct {
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	publisher amqpPublisher
+
+	urlsQueue    string
+	resultsQueue string
+	deliveries   <-chan amqp.Delivery
+
+	mu sync.Mutex
+	// pending is keyed by delivery tag rather than by URL: with Parallel > 1
+	// the same URL can legitimately be in flight twice at once (dedup only
+	// happens at Lookup/Visited.MarkSeen time, not at push time), and two
+	// deliveries sharing a URL key would let the second overwrite the
+	// first, stranding it unacked.
+	pending map[uint64]amqp.Delivery
+}
+
+// NewAMQPFrontier dials addr and declares the durable queues used to
+// distribute crawl work and collect results. See AMQPFrontier for the
+// single-process completion-tracking caveat.
+func NewAMQPFrontier(addr, urlsQueue, resultsQueue string) (*AMQPFrontier, error) {
+	conn, err := amqp.Dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := conn.Channel()
+	if err != nil {
+		return nil, err
+	}
+	for _, queue := range []string{urlsQueue, resultsQueue} {
+		if _, err := channel.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+			return nil, err
+		}
+	}
+	// Only ever hand one unacked message to a worker at a time, so crashed
+	// workers don't strand a large batch of in-flight URLs
+	if err := channel.Qos(1, 0, false); err != nil {
+		return nil, err
+	}
+	deliveries, err := channel.Consume(urlsQueue, "", false, false, false, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AMQPFrontier{
+		conn:         conn,
+		channel:      channel,
+		publisher:    channel,
+		urlsQueue:    urlsQueue,
+		resultsQueue: resultsQueue,
+		deliveries:   deliveries,
+		pending:      make(map[uint64]amqp.Delivery),
+	}, nil
+}
+
+// Push implements Frontier, publishing l as a persistent message
+func (f *AMQPFrontier) Push(l Lookup) error {
+	body, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return f.publisher.Publish("", f.urlsQueue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+}
+
+// Pop implements Frontier, keeping track of the delivery by tag so a
+// matching Ack call can acknowledge it
+func (f *AMQPFrontier) Pop(ctx context.Context) (Lookup, error) {
+	select {
+	case d, ok := <-f.deliveries:
+		if !ok {
+			return Lookup{}, fmt.Errorf("amqp frontier: delivery channel closed")
+		}
+		var l Lookup
+		if err := json.Unmarshal(d.Body, &l); err != nil {
+			_ = d.Nack(false, false)
+			return Lookup{}, err
+		}
+		f.mu.Lock()
+		f.pending[d.DeliveryTag] = d
+		f.mu.Unlock()
+		l.ackToken = d.DeliveryTag
+		return l, nil
+	case <-ctx.Done():
+		return Lookup{}, ctx.Err()
+	}
+}
+
+// resultMessage is published to the results queue after a URL has been
+// fully processed, so a separate aggregator process can track progress
+type resultMessage struct {
+	URL          string `json:"url"`
+	NextURLCount int    `json:"next_url_count"`
+}
+
+// Ack implements Frontier, acknowledging l's delivery and publishing its
+// result count to the results queue
+func (f *AMQPFrontier) Ack(l Lookup, nextURLCount int) error {
+	f.mu.Lock()
+	d, ok := f.pending[l.ackToken]
+	delete(f.pending, l.ackToken)
+	f.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("amqp frontier: no pending delivery for tag %d", l.ackToken)
+	}
+	if err := d.Ack(false); err != nil {
+		return err
+	}
+	body, err := json.Marshal(resultMessage{URL: l.URL, NextURLCount: nextURLCount})
+	if err != nil {
+		return err
+	}
+	return f.publisher.Publish("", f.resultsQueue, false, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
+	})
+}
+
+// Close tears down the channel and connection backing the frontier
+func (f *AMQPFrontier) Close() error {
+	if err := f.channel.Close(); err != nil {
+		return err
+	}
+	return f.conn.Close()
+}
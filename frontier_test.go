@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/suite"
+)
+
+// FrontierTestSuite test suite for InMemoryFrontier and AMQPFrontier
+type FrontierTestSuite struct {
+	suite.Suite
+}
+
+func (s *FrontierTestSuite) TestInMemoryFrontierPushPopAck() {
+	f := NewInMemoryFrontier()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l, err := f.Pop(context.Background())
+		s.Require().NoError(err)
+		s.Equal("https://test.pro", l.URL)
+		s.Require().NoError(f.Ack(l, 0))
+	}()
+	s.Require().NoError(f.Push(Lookup{URL: "https://test.pro"}))
+	<-done
+}
+
+func (s *FrontierTestSuite) TestInMemoryFrontierPopCancelled() {
+	f := NewInMemoryFrontier()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := f.Pop(ctx)
+	s.Error(err)
+}
+
+// TestInMemoryFrontierConcurrentPushPop pushes from many goroutines while a
+// single consumer pops, making sure every Push is matched by a Pop and none
+// is left blocked. A worker's own follow-on URLs are pushed from a background
+// goroutine by queueSeeds, since a synchronous Push on this unbuffered
+// frontier would wait for another Pop.
+func (s *FrontierTestSuite) TestInMemoryFrontierConcurrentPushPop() {
+	f := NewInMemoryFrontier()
+	const n = 20
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			s.Require().NoError(f.Push(Lookup{URL: "https://test.pro"}))
+		}()
+	}
+	for i := 0; i < n; i++ {
+		l, err := f.Pop(context.Background())
+		s.Require().NoError(err)
+		s.Require().NoError(f.Ack(l, 0))
+	}
+	wg.Wait()
+}
+
+// fakeAcknowledger stands in for the amqp.Acknowledger a live broker
+// connection would supply, recording which delivery tags get acked
+type fakeAcknowledger struct {
+	mu    sync.Mutex
+	acked []uint64
+}
+
+func (f *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.acked = append(f.acked, tag)
+	return nil
+}
+
+func (f *fakeAcknowledger) Nack(tag uint64, multiple bool, requeue bool) error { return nil }
+func (f *fakeAcknowledger) Reject(tag uint64, requeue bool) error              { return nil }
+
+// fakePublisher stands in for the *amqp.Channel AMQPFrontier publishes
+// through, recording messages instead of requiring a live broker connection
+type fakePublisher struct {
+	mu        sync.Mutex
+	published []amqp.Publishing
+}
+
+func (f *fakePublisher) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = append(f.published, msg)
+	return nil
+}
+
+func newTestAMQPFrontier(deliveries chan amqp.Delivery, publisher *fakePublisher) *AMQPFrontier {
+	return &AMQPFrontier{
+		urlsQueue:    amqpURLsQueue,
+		resultsQueue: amqpResultsQueue,
+		deliveries:   deliveries,
+		publisher:    publisher,
+		pending:      make(map[uint64]amqp.Delivery),
+	}
+}
+
+func deliveryFor(s *FrontierTestSuite, l Lookup, tag uint64, ack *fakeAcknowledger) amqp.Delivery {
+	body, err := json.Marshal(l)
+	s.Require().NoError(err)
+	return amqp.Delivery{Acknowledger: ack, DeliveryTag: tag, Body: body}
+}
+
+// TestAMQPFrontierPendingKeyedByDeliveryTagNotURL covers the case where the
+// same URL is in flight twice at once (possible with Parallel > 1, since
+// dedup happens at Lookup/Visited.MarkSeen time, not at push time). Keying
+// pending by URL would let the second Pop's delivery silently overwrite the
+// first's, stranding it unacked.
+func (s *FrontierTestSuite) TestAMQPFrontierPendingKeyedByDeliveryTagNotURL() {
+	deliveries := make(chan amqp.Delivery, 2)
+	publisher := &fakePublisher{}
+	ack := &fakeAcknowledger{}
+	f := newTestAMQPFrontier(deliveries, publisher)
+
+	deliveries <- deliveryFor(s, Lookup{URL: "https://test.pro/dup"}, 1, ack)
+	deliveries <- deliveryFor(s, Lookup{URL: "https://test.pro/dup"}, 2, ack)
+
+	first, err := f.Pop(context.Background())
+	s.Require().NoError(err)
+	second, err := f.Pop(context.Background())
+	s.Require().NoError(err)
+
+	s.Require().NoError(f.Ack(first, 0))
+	s.Require().NoError(f.Ack(second, 0))
+	s.ElementsMatch([]uint64{1, 2}, ack.acked)
+}
+
+func (s *FrontierTestSuite) TestAMQPFrontierAckUnknownTokenErrors() {
+	f := newTestAMQPFrontier(make(chan amqp.Delivery), &fakePublisher{})
+	err := f.Ack(Lookup{URL: "https://test.pro"}, 0)
+	s.Error(err)
+}
+
+func TestFrontierTestSuite(t *testing.T) {
+	suite.Run(t, new(FrontierTestSuite))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LoggerTestSuite test suite for StdLogger and FileErrorLogger
+type LoggerTestSuite struct {
+	suite.Suite
+}
+
+func (s *LoggerTestSuite) TestStdLoggerDebugGatedOnVerbose() {
+	var buf bytes.Buffer
+	logger := &StdLogger{Out: &buf, Verbose: false}
+	logger.Debug("hidden", "key", "value")
+	s.Empty(buf.String())
+
+	logger.Verbose = true
+	logger.Debug("shown", "key", "value")
+	s.Equal("shown key=value\n", buf.String())
+}
+
+func (s *LoggerTestSuite) TestStdLoggerInfoAndErrorAlwaysWrite() {
+	var buf bytes.Buffer
+	logger := &StdLogger{Out: &buf, Verbose: false}
+	logger.Info("info msg", "a", 1)
+	logger.Error("error msg", "b", 2)
+	s.Equal("info msg a=1\nerror msg b=2\n", buf.String())
+}
+
+func (s *LoggerTestSuite) TestFileErrorLoggerAppendsJSONLines() {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, "errors.jsonl")
+	logger, err := NewFileErrorLogger(path)
+	s.Require().NoError(err)
+
+	s.Require().NoError(logger.Log(ErrorRecord{URL: "https://test.pro/a", Depth: 1, StatusCode: 500, Error: "boom"}))
+	s.Require().NoError(logger.Close())
+
+	b, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Contains(string(b), `"url":"https://test.pro/a"`)
+	s.Contains(string(b), `"status_code":500`)
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OfflineWriter mirrors crawled pages to disk as the crawl progresses and,
+// once the crawl has finished, rewrites their links so the mirror can be
+// browsed without a network connection
+type OfflineWriter struct {
+	Dir      string
+	Throttle time.Duration
+
+	mu         sync.Mutex
+	locks      map[string]*sync.Mutex
+	pathsByURL map[string]string
+}
+
+// NewOfflineWriter creates a writer that mirrors pages under dir, waiting
+// throttle between writes
+func NewOfflineWriter(dir string, throttle time.Duration) *OfflineWriter {
+	return &OfflineWriter{
+		Dir:        dir,
+		Throttle:   throttle,
+		locks:      make(map[string]*sync.Mutex),
+		pathsByURL: make(map[string]string),
+	}
+}
+
+// Save writes body to disk under the writer's directory, keyed by pageURL's
+// host and path, and records the mapping so RewriteLinks can later resolve it
+func (w *OfflineWriter) Save(pageURL string, body []byte) error {
+	time.Sleep(w.Throttle)
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return err
+	}
+	localPath := w.localPath(u)
+
+	lock := w.lockFor(localPath)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(localPath, body, 0o644); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.pathsByURL[pageURL] = localPath
+	w.mu.Unlock()
+	return nil
+}
+
+// localPath derives the on-disk location for u, treating directory-ish paths
+// (empty or trailing slash) as index.html
+func (w *OfflineWriter) localPath(u *url.URL) string {
+	p := u.Path
+	if p == "" || strings.HasSuffix(p, "/") {
+		p += "index.html"
+	}
+	return filepath.Join(w.Dir, u.Host, filepath.FromSlash(p))
+}
+
+// lockFor returns the per-path mutex used to guard concurrent writes to the
+// same mirrored file
+func (w *OfflineWriter) lockFor(path string) *sync.Mutex {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lock, ok := w.locks[path]
+	if !ok {
+		lock = &sync.Mutex{}
+		w.locks[path] = lock
+	}
+	return lock
+}
+
+var (
+	// hrefSrcRe matches both double- and single-quoted href/src attributes,
+	// since hand-written and templated HTML uses either
+	hrefSrcRe = regexp.MustCompile(`(href|src)=("[^"]*"|'[^']*')`)
+	cssURLRe  = regexp.MustCompile(`url\(['"]?([^'")]*)['"]?\)`)
+)
+
+// RewriteLinks walks every saved file and rewrites href, src, and CSS
+// url(...) references so they point at the sibling local copy of any URL
+// that was actually visited, leaving external/unvisited URLs untouched
+func (w *OfflineWriter) RewriteLinks() error {
+	w.mu.Lock()
+	paths := make(map[string]string, len(w.pathsByURL))
+	for u, p := range w.pathsByURL {
+		paths[u] = p
+	}
+	w.mu.Unlock()
+
+	for pageURL, localPath := range paths {
+		if err := w.rewriteFile(pageURL, localPath, paths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteFile rewrites the links found in the file saved at localPath for
+// pageURL, resolving each reference against pageURL before checking whether
+// it was mirrored
+func (w *OfflineWriter) rewriteFile(pageURL, localPath string, paths map[string]string) error {
+	content, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return err
+	}
+
+	resolve := func(ref string) (string, bool) {
+		parsed, err := url.Parse(ref)
+		if err != nil {
+			return "", false
+		}
+		target, ok := paths[base.ResolveReference(parsed).String()]
+		if !ok {
+			return "", false
+		}
+		rel, err := filepath.Rel(filepath.Dir(localPath), target)
+		if err != nil {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+
+	rewritten := hrefSrcRe.ReplaceAllFunc(content, func(m []byte) []byte {
+		sub := hrefSrcRe.FindSubmatch(m)
+		quoted := string(sub[2])
+		value := quoted[1 : len(quoted)-1]
+		if local, ok := resolve(value); ok {
+			return []byte(fmt.Sprintf(`%s="%s"`, sub[1], local))
+		}
+		return m
+	})
+	rewritten = cssURLRe.ReplaceAllFunc(rewritten, func(m []byte) []byte {
+		sub := cssURLRe.FindSubmatch(m)
+		if local, ok := resolve(string(sub[1])); ok {
+			return []byte(fmt.Sprintf(`url(%s)`, local))
+		}
+		return m
+	})
+
+	return os.WriteFile(localPath, rewritten, 0o644)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// OfflineWriterTestSuite test suite for the offline mirror writer
+type OfflineWriterTestSuite struct {
+	suite.Suite
+}
+
+func (s *OfflineWriterTestSuite) TestSaveAndRewriteLinks() {
+	dir := s.T().TempDir()
+	writer := NewOfflineWriter(dir, 0)
+
+	err := writer.Save("https://test.pro/", []byte(`<a href="/about">about</a><a href="https://external.com">external</a>`))
+	s.Require().NoError(err)
+	err = writer.Save("https://test.pro/about", []byte(`<a href="/">home</a>`))
+	s.Require().NoError(err)
+
+	err = writer.RewriteLinks()
+	s.Require().NoError(err)
+
+	index, err := os.ReadFile(filepath.Join(dir, "test.pro", "index.html"))
+	s.Require().NoError(err)
+	s.Contains(string(index), `href="about"`)
+	s.Contains(string(index), `href="https://external.com"`)
+
+	about, err := os.ReadFile(filepath.Join(dir, "test.pro", "about"))
+	s.Require().NoError(err)
+	s.Contains(string(about), `href="index.html"`)
+}
+
+func (s *OfflineWriterTestSuite) TestRewriteLinksHandlesSingleQuotedAttrs() {
+	dir := s.T().TempDir()
+	writer := NewOfflineWriter(dir, 0)
+
+	err := writer.Save("https://test.pro/", []byte(`<a href='/about'>about</a>`))
+	s.Require().NoError(err)
+	err = writer.Save("https://test.pro/about", []byte(`<a href="/">home</a>`))
+	s.Require().NoError(err)
+
+	err = writer.RewriteLinks()
+	s.Require().NoError(err)
+
+	index, err := os.ReadFile(filepath.Join(dir, "test.pro", "index.html"))
+	s.Require().NoError(err)
+	s.Contains(string(index), `href="about"`)
+}
+
+func (s *OfflineWriterTestSuite) TestLocalPathDirectoryIsh() {
+	writer := NewOfflineWriter("/mirror", 0)
+	u, err := url.Parse("https://test.pro/blog/")
+	s.Require().NoError(err)
+	s.Equal(filepath.Join("/mirror", "test.pro", "blog", "index.html"), writer.localPath(u))
+}
+
+func TestOfflineWriterTestSuite(t *testing.T) {
+	suite.Run(t, new(OfflineWriterTestSuite))
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SeedProvider discovers additional seed URLs for a host before the crawl
+// starts, pushing each one onto the crawler's Frontier as a depth-0 Lookup.
+// Seed returns how many Lookups it queued so callers can log it.
+type SeedProvider interface {
+	Seed(c *Crawler) (int, error)
+}
+
+// queueSeeds pushes urls onto c.Frontier as Lookups at depth. Pending is
+// incremented synchronously for every url before queueSeeds returns, so a
+// caller can queue seeds concurrently with waitUntilDone's Pending.Wait()
+// without racing a sync.WaitGroup Add against a Wait that has already
+// observed a zero counter, and so a worker queueing its own follow-on URLs
+// never has to wait on itself to free up the Frontier (which would deadlock
+// an unbuffered InMemoryFrontier running with -p 1). The actual Frontier.Push
+// calls happen from a background goroutine so a caller with many urls never
+// blocks waiting for a consumer to drain the frontier.
+func queueSeeds(c *Crawler, urls []string, depth int) {
+	c.Pending.Add(len(urls))
+	go func() {
+		for _, u := range urls {
+			if err := c.Frontier.Push(Lookup{URL: u, Depth: depth}); err != nil {
+				c.Logger.Debug("error pushing url onto frontier", "url", u, "error", err.Error())
+				c.Pending.Done()
+			}
+		}
+	}()
+}
+
+// RobotsRules holds the Disallow/Allow path rules parsed from a robots.txt
+type RobotsRules struct {
+	Disallow []string
+	Allow    []string
+}
+
+// Allowed reports whether path is permitted by the parsed robots rules. The
+// longest matching rule wins; Allow wins ties over Disallow, matching the
+// de-facto robots.txt resolution most crawlers use.
+func (r *RobotsRules) Allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+	allowed := true
+	longest := -1
+	for _, rule := range r.Disallow {
+		if rule != "" && strings.HasPrefix(path, rule) && len(rule) > longest {
+			longest = len(rule)
+			allowed = false
+		}
+	}
+	for _, rule := range r.Allow {
+		if rule != "" && strings.HasPrefix(path, rule) && len(rule) >= longest {
+			longest = len(rule)
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// RobotsProvider fetches a host's robots.txt, builds the Allow/Disallow
+// rules Crawler.allowed checks against, honours Crawl-delay, and collects
+// any Sitemap directives for RobotsSitemaps to hand off to a SitemapProvider
+type RobotsProvider struct {
+	HTTPClient HTTPClient
+}
+
+// Seed implements SeedProvider. It never queues Lookups itself; it only
+// installs robots rules, applies Crawl-delay, and records any Sitemap
+// directives onto c.RobotsSitemaps for a SitemapProvider to expand.
+func (p RobotsProvider) Seed(c *Crawler) (int, error) {
+	req, err := http.NewRequestWithContext(c.Ctx, "GET", fmt.Sprintf("https://%s/robots.txt", c.Host), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, nil
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	rules := &RobotsRules{}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(lower, "disallow:"):
+			rules.Disallow = append(rules.Disallow, strings.TrimSpace(line[len("Disallow:"):]))
+		case strings.HasPrefix(lower, "allow:"):
+			rules.Allow = append(rules.Allow, strings.TrimSpace(line[len("Allow:"):]))
+		case strings.HasPrefix(lower, "sitemap:"):
+			c.RobotsSitemaps = append(c.RobotsSitemaps, strings.TrimSpace(line[len("Sitemap:"):]))
+		case strings.HasPrefix(lower, "crawl-delay:"):
+			seconds, err := strconv.ParseFloat(strings.TrimSpace(line[len("Crawl-delay:"):]), 64)
+			if err != nil {
+				continue
+			}
+			if delay := time.Duration(seconds * float64(time.Second)); delay > c.Opts.Limit {
+				c.Opts.Limit = delay
+			}
+		}
+	}
+	c.Robots = rules
+	return 0, nil
+}
+
+// sitemapIndex is the root of a sitemap index document, which points at
+// further sitemaps rather than listing pages directly
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// urlSet is the root of a leaf sitemap document listing crawlable pages
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// SitemapProvider recursively parses a sitemap index and the sitemaps it
+// references (gzip aware), emitting every page <loc> it finds as a seed. If
+// Locations is empty it falls back to the host's conventional sitemap.xml.
+type SitemapProvider struct {
+	HTTPClient HTTPClient
+	Locations  []string
+}
+
+// Seed implements SeedProvider
+func (p SitemapProvider) Seed(c *Crawler) (int, error) {
+	queue := p.Locations
+	if len(queue) == 0 {
+		queue = []string{fmt.Sprintf("https://%s/sitemap.xml", c.Host)}
+	}
+	seen := make(map[string]struct{})
+	found := []string{}
+	for len(queue) > 0 {
+		loc := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[loc]; ok {
+			continue
+		}
+		seen[loc] = struct{}{}
+
+		body, err := p.fetch(c, loc)
+		if err != nil {
+			c.Logger.Debug("sitemap fetch error", "url", loc, "error", err.Error())
+			continue
+		}
+
+		var index sitemapIndex
+		if err := xml.Unmarshal(body, &index); err == nil && len(index.Sitemaps) > 0 {
+			for _, sitemap := range index.Sitemaps {
+				queue = append(queue, sitemap.Loc)
+			}
+			continue
+		}
+
+		var set urlSet
+		if err := xml.Unmarshal(body, &set); err != nil {
+			continue
+		}
+		for _, u := range set.URLs {
+			found = append(found, u.Loc)
+		}
+	}
+	queueSeeds(c, found, 0)
+	return len(found), nil
+}
+
+// fetch retrieves loc, transparently decompressing it when it is gzipped
+func (p SitemapProvider) fetch(c *Crawler, loc string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.Ctx, "GET", loc, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var reader io.Reader = resp.Body
+	if strings.HasSuffix(loc, ".gz") || resp.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	return io.ReadAll(reader)
+}
+
+// ArchiveProvider harvests historical URLs for a host from the Wayback
+// Machine's CDX API and the Common Crawl index, emitting each as a seed
+type ArchiveProvider struct {
+	HTTPClient HTTPClient
+	// CommonCrawlIndex selects which Common Crawl crawl to query, e.g.
+	// "CC-MAIN-2024-10". Defaults to a recent index if left empty.
+	CommonCrawlIndex string
+}
+
+// Seed implements SeedProvider
+func (p ArchiveProvider) Seed(c *Crawler) (int, error) {
+	wayback, err := p.seedWayback(c)
+	if err != nil {
+		c.Logger.Debug("wayback archive error", "error", err.Error())
+	}
+	commonCrawl, err := p.seedCommonCrawl(c)
+	if err != nil {
+		c.Logger.Debug("common crawl archive error", "error", err.Error())
+	}
+	found := append(wayback, commonCrawl...)
+	queueSeeds(c, found, 0)
+	return len(found), nil
+}
+
+// seedWayback queries web.archive.org's CDX API, whose response is a JSON
+// array of rows with the field names as the first row
+func (p ArchiveProvider) seedWayback(c *Crawler) ([]string, error) {
+	body, err := p.get(c, fmt.Sprintf("https://web.archive.org/cdx/search/cdx?url=%s/*&output=json&collapse=urlkey", c.Host))
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	if err := json.Unmarshal(body, &rows); err != nil {
+		return nil, err
+	}
+	found := []string{}
+	for i, row := range rows {
+		if i == 0 || len(row) < 3 {
+			// First row is the CDX field header, not a result
+			continue
+		}
+		found = append(found, row[2])
+	}
+	return found, nil
+}
+
+// seedCommonCrawl queries index.commoncrawl.org, whose response is JSON
+// Lines rather than a JSON array
+func (p ArchiveProvider) seedCommonCrawl(c *Crawler) ([]string, error) {
+	index := p.CommonCrawlIndex
+	if index == "" {
+		index = "CC-MAIN-2024-10"
+	}
+	body, err := p.get(c, fmt.Sprintf("https://index.commoncrawl.org/%s-index?url=%s/*&output=json", index, c.Host))
+	if err != nil {
+		return nil, err
+	}
+	found := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		if rec.URL != "" {
+			found = append(found, rec.URL)
+		}
+	}
+	return found, nil
+}
+
+func (p ArchiveProvider) get(c *Crawler, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.Ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// SeedTestSuite test suite for the robots/sitemap/archive seed providers
+type SeedTestSuite struct {
+	suite.Suite
+}
+
+func (s *SeedTestSuite) TestRobotsRulesAllowed() {
+	rules := &RobotsRules{
+		Disallow: []string{"/private"},
+		Allow:    []string{"/private/public"},
+	}
+	s.True(rules.Allowed("/blog"))
+	s.False(rules.Allowed("/private/secret"))
+	s.True(rules.Allowed("/private/public/page"))
+	s.True((*RobotsRules)(nil).Allowed("/anything"))
+}
+
+func (s *SeedTestSuite) TestRobotsProviderSeed() {
+	mockedClient := &ClientMock{}
+	body := "Disallow: /private\nAllow: /private/public\nCrawl-delay: 2\nSitemap: https://test.pro/sitemap.xml\n"
+	resp := GetMockHTTPResponse([]byte(body), 200)
+	defer resp.Body.Close()
+	mockedClient.On("Do", mock.Anything).Return(resp, nil)
+
+	crawler, err := NewCrawler("https://test.pro", mockedClient, &Opts{Parallel: 1}, NewInMemoryFrontier(), NewInMemoryVisited())
+	s.Require().NoError(err)
+
+	n, err := RobotsProvider{HTTPClient: mockedClient}.Seed(crawler)
+	s.Require().NoError(err)
+	s.Equal(0, n)
+	s.False(crawler.allowed(mustParseURL(s, "https://test.pro/private/secret")))
+	s.True(crawler.allowed(mustParseURL(s, "https://test.pro/private/public/page")))
+	s.Equal(time.Second*2, crawler.Opts.Limit)
+	s.Equal([]string{"https://test.pro/sitemap.xml"}, crawler.RobotsSitemaps)
+}
+
+func (s *SeedTestSuite) TestSitemapProviderSeed() {
+	mockedClient := &ClientMock{}
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url><loc>https://test.pro/a</loc></url>
+	<url><loc>https://test.pro/b</loc></url>
+</urlset>`
+	resp := GetMockHTTPResponse([]byte(body), 200)
+	defer resp.Body.Close()
+	mockedClient.On("Do", mock.Anything).Return(resp, nil)
+
+	crawler, err := NewCrawler("https://test.pro", mockedClient, &Opts{Parallel: 1}, NewInMemoryFrontier(), NewInMemoryVisited())
+	s.Require().NoError(err)
+
+	n, err := SitemapProvider{HTTPClient: mockedClient}.Seed(crawler)
+	s.Require().NoError(err)
+	s.Equal(2, n)
+
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		lookup, err := crawler.Frontier.Pop(crawler.Ctx)
+		s.Require().NoError(err)
+		seen[lookup.URL] = true
+	}
+	s.True(seen["https://test.pro/a"])
+	s.True(seen["https://test.pro/b"])
+}
+
+func (s *SeedTestSuite) TestArchiveProviderSeedWayback() {
+	mockedClient := &ClientMock{}
+	body := `[["urlkey","timestamp","original","mimetype","statuscode","digest","length"],
+["pro,test)/a","20200101000000","https://test.pro/a","text/html","200","a","1"],
+["pro,test)/b","20200102000000","https://test.pro/b","text/html","200","b","1"]]`
+	resp := GetMockHTTPResponse([]byte(body), 200)
+	defer resp.Body.Close()
+	mockedClient.On("Do", mock.Anything).Return(resp, nil)
+
+	crawler, err := NewCrawler("https://test.pro", mockedClient, &Opts{Parallel: 1}, NewInMemoryFrontier(), NewInMemoryVisited())
+	s.Require().NoError(err)
+
+	found, err := (ArchiveProvider{HTTPClient: mockedClient}).seedWayback(crawler)
+	s.Require().NoError(err)
+	s.Equal([]string{"https://test.pro/a", "https://test.pro/b"}, found)
+}
+
+func (s *SeedTestSuite) TestArchiveProviderSeedWaybackNoDataRows() {
+	mockedClient := &ClientMock{}
+	body := `[["urlkey","timestamp","original","mimetype","statuscode","digest","length"]]`
+	resp := GetMockHTTPResponse([]byte(body), 200)
+	defer resp.Body.Close()
+	mockedClient.On("Do", mock.Anything).Return(resp, nil)
+
+	crawler, err := NewCrawler("https://test.pro", mockedClient, &Opts{Parallel: 1}, NewInMemoryFrontier(), NewInMemoryVisited())
+	s.Require().NoError(err)
+
+	found, err := (ArchiveProvider{HTTPClient: mockedClient}).seedWayback(crawler)
+	s.Require().NoError(err)
+	s.Empty(found)
+}
+
+func (s *SeedTestSuite) TestArchiveProviderSeedCommonCrawl() {
+	mockedClient := &ClientMock{}
+	body := "  \n" + `{"url": "https://test.pro/a", "timestamp": "20200101000000"}` + "\n  " +
+		`{"url": "https://test.pro/b", "timestamp": "20200102000000"}` + "  \n"
+	resp := GetMockHTTPResponse([]byte(body), 200)
+	defer resp.Body.Close()
+	mockedClient.On("Do", mock.Anything).Return(resp, nil)
+
+	crawler, err := NewCrawler("https://test.pro", mockedClient, &Opts{Parallel: 1}, NewInMemoryFrontier(), NewInMemoryVisited())
+	s.Require().NoError(err)
+
+	found, err := (ArchiveProvider{HTTPClient: mockedClient}).seedCommonCrawl(crawler)
+	s.Require().NoError(err)
+	s.Equal([]string{"https://test.pro/a", "https://test.pro/b"}, found)
+}
+
+func (s *SeedTestSuite) TestArchiveProviderSeed() {
+	mockedClient := &ClientMock{}
+	waybackBody := `[["urlkey","timestamp","original","mimetype","statuscode","digest","length"],
+["pro,test)/a","20200101000000","https://test.pro/a","text/html","200","a","1"]]`
+	commonCrawlBody := `{"url": "https://test.pro/b", "timestamp": "20200102000000"}`
+	mockedClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return strings.Contains(req.URL.String(), "web.archive.org")
+	})).Return(GetMockHTTPResponse([]byte(waybackBody), 200), nil)
+	mockedClient.On("Do", mock.MatchedBy(func(req *http.Request) bool {
+		return strings.Contains(req.URL.String(), "commoncrawl.org")
+	})).Return(GetMockHTTPResponse([]byte(commonCrawlBody), 200), nil)
+
+	crawler, err := NewCrawler("https://test.pro", mockedClient, &Opts{Parallel: 1}, NewInMemoryFrontier(), NewInMemoryVisited())
+	s.Require().NoError(err)
+
+	n, err := (ArchiveProvider{HTTPClient: mockedClient}).Seed(crawler)
+	s.Require().NoError(err)
+	s.Equal(2, n)
+
+	seen := map[string]bool{}
+	for i := 0; i < n; i++ {
+		lookup, err := crawler.Frontier.Pop(crawler.Ctx)
+		s.Require().NoError(err)
+		seen[lookup.URL] = true
+	}
+	s.True(seen["https://test.pro/a"])
+	s.True(seen["https://test.pro/b"])
+}
+
+func mustParseURL(s *SeedTestSuite, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	s.Require().NoError(err)
+	return u
+}
+
+func TestSeedTestSuite(t *testing.T) {
+	suite.Run(t, new(SeedTestSuite))
+}
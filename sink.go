@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+)
+
+// ResultSink receives each Count once a Lookup has finished, decoupling how
+// results are reported (stdout, JSON Lines, CSV) from the crawl loop itself
+type ResultSink interface {
+	Write(ctx context.Context, count Count) error
+	Close() error
+}
+
+// StdoutSink writes one tab-separated host/words/numbers/path line per Count
+type StdoutSink struct {
+	Out io.Writer
+}
+
+// NewStdoutSink creates a StdoutSink writing to out
+func NewStdoutSink(out io.Writer) *StdoutSink {
+	return &StdoutSink{Out: out}
+}
+
+// Write implements ResultSink
+func (s *StdoutSink) Write(_ context.Context, count Count) error {
+	u, err := url.Parse(count.URL)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.Out, "%s\t\t%d\t%d\t\t%s\n", u.Host, count.Words, count.Numbers, u.Path)
+	return err
+}
+
+// Close implements ResultSink
+func (s *StdoutSink) Close() error {
+	return nil
+}
+
+// JSONLSink writes one JSON-encoded Count per line
+type JSONLSink struct {
+	enc *json.Encoder
+}
+
+// NewJSONLSink creates a JSONLSink writing to out
+func NewJSONLSink(out io.Writer) *JSONLSink {
+	return &JSONLSink{enc: json.NewEncoder(out)}
+}
+
+// Write implements ResultSink
+func (s *JSONLSink) Write(_ context.Context, count Count) error {
+	return s.enc.Encode(count)
+}
+
+// Close implements ResultSink
+func (s *JSONLSink) Close() error {
+	return nil
+}
+
+// CSVSink writes Counts as url,words,numbers CSV rows
+type CSVSink struct {
+	w *csv.Writer
+}
+
+// NewCSVSink creates a CSVSink writing to out
+func NewCSVSink(out io.Writer) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(out)}
+}
+
+// Write implements ResultSink
+func (s *CSVSink) Write(_ context.Context, count Count) error {
+	if err := s.w.Write([]string{count.URL, fmt.Sprint(count.Words), fmt.Sprint(count.Numbers)}); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// Close implements ResultSink
+func (s *CSVSink) Close() error {
+	return nil
+}
+
+// nopCloser adapts an io.Writer that must not be closed, such as os.Stdout,
+// to io.WriteCloser
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// closingSink pairs a ResultSink with the io.Closer backing the writer it
+// was built from, so Close also releases a sink's underlying file
+type closingSink struct {
+	ResultSink
+	out io.Closer
+}
+
+// Close implements ResultSink
+func (s closingSink) Close() error {
+	if err := s.ResultSink.Close(); err != nil {
+		return err
+	}
+	return s.out.Close()
+}
+
+// openOutput opens opts.Output for writing, truncating it, or returns
+// os.Stdout wrapped so it is never closed when opts.Output is unset
+func openOutput(opts *Opts) (io.WriteCloser, error) {
+	if opts.Output == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.OpenFile(opts.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+}
+
+// buildResultSink constructs the ResultSink selected by opts.Format,
+// writing to opts.Output when set or os.Stdout otherwise
+func buildResultSink(opts *Opts) (ResultSink, error) {
+	out, err := openOutput(opts)
+	if err != nil {
+		return nil, err
+	}
+	var inner ResultSink
+	switch opts.Format {
+	case "json":
+		inner = NewJSONLSink(out)
+	case "csv":
+		inner = NewCSVSink(out)
+	case "", "text":
+		inner = NewStdoutSink(out)
+	default:
+		_ = out.Close()
+		return nil, fmt.Errorf("unknown output format: %s", opts.Format)
+	}
+	return closingSink{ResultSink: inner, out: out}, nil
+}
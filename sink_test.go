@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SinkTestSuite test suite for the ResultSink implementations
+type SinkTestSuite struct {
+	suite.Suite
+}
+
+func (s *SinkTestSuite) TestStdoutSinkFormat() {
+	var buf bytes.Buffer
+	sink := NewStdoutSink(&buf)
+	s.Require().NoError(sink.Write(context.Background(), Count{URL: "https://test.pro/a", Words: 3, Numbers: 1}))
+	s.Equal("test.pro\t\t3\t1\t\t/a\n", buf.String())
+}
+
+func (s *SinkTestSuite) TestJSONLSink() {
+	var buf bytes.Buffer
+	sink := NewJSONLSink(&buf)
+	s.Require().NoError(sink.Write(context.Background(), Count{URL: "https://test.pro/a", Words: 3, Numbers: 1}))
+	s.JSONEq(`{"URL":"https://test.pro/a","Words":3,"Numbers":1}`, buf.String())
+}
+
+func (s *SinkTestSuite) TestCSVSink() {
+	var buf bytes.Buffer
+	sink := NewCSVSink(&buf)
+	s.Require().NoError(sink.Write(context.Background(), Count{URL: "https://test.pro/a", Words: 3, Numbers: 1}))
+	s.Equal("https://test.pro/a,3,1\n", buf.String())
+}
+
+func (s *SinkTestSuite) TestBuildResultSinkUnknownFormat() {
+	_, err := buildResultSink(&Opts{Format: "xml"})
+	s.Error(err)
+}
+
+func (s *SinkTestSuite) TestBuildResultSinkWritesToOutputFile() {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, "results.jsonl")
+	sink, err := buildResultSink(&Opts{Format: "json", Output: path})
+	s.Require().NoError(err)
+	s.Require().NoError(sink.Write(context.Background(), Count{URL: "https://test.pro/a", Words: 1, Numbers: 0}))
+	s.Require().NoError(sink.Close())
+
+	b, err := os.ReadFile(path)
+	s.Require().NoError(err)
+	s.Contains(string(b), `"URL":"https://test.pro/a"`)
+}
+
+func TestSinkTestSuite(t *testing.T) {
+	suite.Run(t, new(SinkTestSuite))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TransportTestSuite test suite for the hardened HTTP transport and proxy pool
+type TransportTestSuite struct {
+	suite.Suite
+}
+
+func (s *TransportTestSuite) TestProxyPoolRoundRobin() {
+	pool, err := NewProxyPool([]string{"http://proxy-a", "http://proxy-b"})
+	s.Require().NoError(err)
+
+	first, err := pool.Pick()
+	s.Require().NoError(err)
+	second, err := pool.Pick()
+	s.Require().NoError(err)
+	third, err := pool.Pick()
+	s.Require().NoError(err)
+
+	s.Equal("proxy-a", first.Host)
+	s.Equal("proxy-b", second.Host)
+	s.Equal("proxy-a", third.Host)
+}
+
+func (s *TransportTestSuite) TestProxyPoolEmpty() {
+	pool, err := NewProxyPool(nil)
+	s.Require().NoError(err)
+	proxy, err := pool.Pick()
+	s.Require().NoError(err)
+	s.Nil(proxy)
+}
+
+func (s *TransportTestSuite) TestLoadProxiesCombinesFlagAndFile() {
+	dir := s.T().TempDir()
+	path := filepath.Join(dir, "proxies.txt")
+	s.Require().NoError(os.WriteFile(path, []byte("http://proxy-b\n\nhttp://proxy-c\n"), 0o600))
+
+	proxies, err := loadProxies(&Opts{Proxy: "http://proxy-a", ProxyFile: path})
+	s.Require().NoError(err)
+	s.Equal([]string{"http://proxy-a", "http://proxy-b", "http://proxy-c"}, proxies)
+}
+
+func (s *TransportTestSuite) TestBuildTransportAppliesInsecureAndProxy() {
+	transport, err := buildTransport(&Opts{Insecure: true, Proxy: "http://proxy-a"})
+	s.Require().NoError(err)
+	s.True(transport.TLSClientConfig.InsecureSkipVerify)
+	s.Require().NotNil(transport.Proxy)
+
+	proxyURL, err := transport.Proxy(nil)
+	s.Require().NoError(err)
+	s.Equal("proxy-a", proxyURL.Host)
+}
+
+func TestTransportTestSuite(t *testing.T) {
+	suite.Run(t, new(TransportTestSuite))
+}
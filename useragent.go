@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// defaultUserAgents are recent desktop Chrome/Firefox/Safari User-Agent
+// strings, used when no pool is supplied so requests don't immediately
+// stand out as coming from Go's default client
+var defaultUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+}
+
+// UserAgentPool hands out a User-Agent string per request, either picking
+// uniformly at random or, when Sticky, reusing the same one for every
+// request to a given host
+type UserAgentPool struct {
+	mu      sync.Mutex
+	agents  []string
+	sticky  bool
+	perHost map[string]string
+}
+
+// NewUserAgentPool creates a UserAgentPool over agents, falling back to
+// defaultUserAgents when agents is empty
+func NewUserAgentPool(agents []string, sticky bool) *UserAgentPool {
+	if len(agents) == 0 {
+		agents = defaultUserAgents
+	}
+	return &UserAgentPool{
+		agents:  agents,
+		sticky:  sticky,
+		perHost: make(map[string]string),
+	}
+}
+
+// Pick returns a User-Agent for host, reusing a previously picked one for
+// repeat calls with the same host when the pool is sticky
+func (p *UserAgentPool) Pick(host string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sticky {
+		if ua, ok := p.perHost[host]; ok {
+			return ua
+		}
+	}
+	ua := p.agents[rand.Intn(len(p.agents))]
+	if p.sticky {
+		p.perHost[host] = ua
+	}
+	return ua
+}
+
+// Refresh replaces the pool's agents with a freshly fetched, newline
+// separated list from url (e.g. a caniuse-derived feed), so version
+// numbers embedded in the defaults don't go stale over a long-running
+// deployment
+func (p *UserAgentPool) Refresh(client HTTPClient, url string) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	agents := make([]string, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			agents = append(agents, line)
+		}
+	}
+	if len(agents) == 0 {
+		return fmt.Errorf("user agent refresh from %s returned no agents", url)
+	}
+	p.mu.Lock()
+	p.agents = agents
+	p.perHost = make(map[string]string)
+	p.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+// UserAgentTestSuite test suite for the UserAgentPool
+type UserAgentTestSuite struct {
+	suite.Suite
+}
+
+func (s *UserAgentTestSuite) TestPickStickyReusesPerHost() {
+	pool := NewUserAgentPool([]string{"ua-a", "ua-b", "ua-c"}, true)
+	first := pool.Pick("a.example")
+	for i := 0; i < 10; i++ {
+		s.Equal(first, pool.Pick("a.example"))
+	}
+	// a different host may get a different agent, but must still be one of
+	// the configured ones
+	s.Contains([]string{"ua-a", "ua-b", "ua-c"}, pool.Pick("b.example"))
+}
+
+func (s *UserAgentTestSuite) TestPickNonStickyStaysWithinPool() {
+	pool := NewUserAgentPool([]string{"ua-a"}, false)
+	s.Equal("ua-a", pool.Pick("a.example"))
+	s.Equal("ua-a", pool.Pick("a.example"))
+}
+
+func (s *UserAgentTestSuite) TestPickDefaultsWhenEmpty() {
+	pool := NewUserAgentPool(nil, false)
+	s.Contains(defaultUserAgents, pool.Pick("a.example"))
+}
+
+func (s *UserAgentTestSuite) TestRefresh() {
+	mockedClient := &ClientMock{}
+	resp := GetMockHTTPResponse([]byte("ua-x\n"), 200)
+	defer resp.Body.Close()
+	mockedClient.On("Do", mock.Anything).Return(resp, nil)
+
+	pool := NewUserAgentPool([]string{"ua-a"}, false)
+	err := pool.Refresh(mockedClient, "https://example.com/uas.txt")
+	s.Require().NoError(err)
+	s.Equal("ua-x", pool.Pick("a.example"))
+}
+
+func TestUserAgentTestSuite(t *testing.T) {
+	suite.Run(t, new(UserAgentTestSuite))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Visited tracks which URLs have already been visited so the same page is
+// never crawled twice. URLs are marked when Lookup runs, not when they are
+// pushed onto the Frontier, so the same URL can still be queued more than once.
+type Visited interface {
+	// MarkSeen records rawURL as visited, reporting whether it had already
+	// been marked so Lookup can tell a first-time visit from a repeat one
+	MarkSeen(rawURL string) (bool, error)
+}
+
+// InMemoryVisited is a Visited backed by a process-local map, preserving
+// the crawler's original single-process dedup semantics
+type InMemoryVisited struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// NewInMemoryVisited creates a Visited whose seen-set only lives in this
+// process's memory
+func NewInMemoryVisited() *InMemoryVisited {
+	return &InMemoryVisited{seen: make(map[string]struct{})}
+}
+
+// MarkSeen implements Visited
+func (v *InMemoryVisited) MarkSeen(rawURL string) (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	_, ok := v.seen[rawURL]
+	v.seen[rawURL] = struct{}{}
+	return ok, nil
+}
+
+// RedisVisited is a Visited backed by Redis SETNX, letting multiple worker
+// processes share a single seen-set
+type RedisVisited struct {
+	Client *redis.Client
+	// TTL expires old entries so a long-running shared seen-set doesn't
+	// grow unbounded; zero means entries never expire
+	TTL time.Duration
+}
+
+// NewRedisVisited creates a Visited backed by the Redis instance at addr
+func NewRedisVisited(addr string, ttl time.Duration) *RedisVisited {
+	return &RedisVisited{
+		Client: redis.NewClient(&redis.Options{Addr: addr}),
+		TTL:    ttl,
+	}
+}
+
+// MarkSeen implements Visited using SETNX, so the "first one to set the key
+// wins" semantics hold even when several workers race on the same URL
+func (v *RedisVisited) MarkSeen(rawURL string) (bool, error) {
+	set, err := v.Client.SetNX(context.Background(), "crawl:visited:"+rawURL, 1, v.TTL).Result()
+	if err != nil {
+		return false, err
+	}
+	return !set, nil
+}
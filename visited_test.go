@@ -0,0 +1,54 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// VisitedTestSuite test suite for InMemoryVisited
+type VisitedTestSuite struct {
+	suite.Suite
+}
+
+func (s *VisitedTestSuite) TestMarkSeenReportsFirstVisitThenRepeat() {
+	v := NewInMemoryVisited()
+	seen, err := v.MarkSeen("https://test.pro")
+	s.Require().NoError(err)
+	s.False(seen)
+
+	seen, err = v.MarkSeen("https://test.pro")
+	s.Require().NoError(err)
+	s.True(seen)
+}
+
+// TestMarkSeenConcurrentOnlyOneFirstVisit guards the "first one to mark wins"
+// semantics under concurrent MarkSeen calls for the same URL, the property
+// Lookup relies on to avoid crawling a page twice
+func (s *VisitedTestSuite) TestMarkSeenConcurrentOnlyOneFirstVisit() {
+	v := NewInMemoryVisited()
+	const n = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	firstVisits := 0
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			seen, err := v.MarkSeen("https://test.pro")
+			s.Require().NoError(err)
+			if !seen {
+				mu.Lock()
+				firstVisits++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	s.Equal(1, firstVisits)
+}
+
+func TestVisitedTestSuite(t *testing.T) {
+	suite.Run(t, new(VisitedTestSuite))
+}